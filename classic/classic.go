@@ -1,15 +1,16 @@
 package niniteclassic
 
 import (
-	"bufio"
-	"errors"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // Refer to https://ninite.com/help/features/switches.html for any missing details.
@@ -29,6 +30,8 @@ type Classic struct {
 	}
 	selectedApps []string
 	excludedApps []string
+	preferences  map[string]string
+	held         []string
 	remote       []string
 	remoteAuth   struct {
 		username string
@@ -57,14 +60,16 @@ type Status struct {
 	Status  string
 	Reason  string
 	Version string
+	Error   error // set when this line could not be parsed, or by RemoteFanOut when a host's run failed; the other fields are then not meaningful
 }
 
 // AppVersion is an available app version
 type AppVersion struct {
 	App              string
 	Version          string
-	CurrentVersion   bool // ???: I don't think this indicates that it is installed nor the version that is presently installed
-	AlternateVersion bool // this indicates that this version has to be selected explicitly in order to be installed
+	CurrentVersion   bool  // ???: I don't think this indicates that it is installed nor the version that is presently installed
+	AlternateVersion bool  // this indicates that this version has to be selected explicitly in order to be installed
+	Error            error // set when this line could not be parsed; the other fields are not meaningful
 }
 
 // AppAudit is an app that may or may not be installed
@@ -73,11 +78,196 @@ type AppAudit struct {
 	Version   string
 	Status    string
 	Installed bool
+	Error     error // set when this line could not be parsed; the other fields are not meaningful
 }
 
-var statusMatch = regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<status>[^\r\n\(\)]+)(?:\s+\((?P<reason>.+)\))?$`)
-var versionMatch = regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<type>[\*\(])?(?P<version>[^\r\n\(\)]+)\)?$`)
-var auditMatch = regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<status>[^\r\n\(\)\-]+)(?:\s+-\s+(?P<version>.+))?$`)
+// MarshalJSON encodes Status with stable, lowercase field names, reducing
+// Error to its message so Status remains marshalable even when Error is set.
+func (s Status) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if s.Error != nil {
+		errMsg = s.Error.Error()
+	}
+	return json.Marshal(struct {
+		App     string `json:"app"`
+		Status  string `json:"status,omitempty"`
+		Reason  string `json:"reason,omitempty"`
+		Version string `json:"version,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}{s.App, s.Status, s.Reason, s.Version, errMsg})
+}
+
+// MarshalJSON encodes AppVersion with stable, lowercase field names, reducing
+// Error to its message so AppVersion remains marshalable even when Error is set.
+func (v AppVersion) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if v.Error != nil {
+		errMsg = v.Error.Error()
+	}
+	return json.Marshal(struct {
+		App              string `json:"app"`
+		Version          string `json:"version,omitempty"`
+		CurrentVersion   bool   `json:"currentVersion"`
+		AlternateVersion bool   `json:"alternateVersion"`
+		Error            string `json:"error,omitempty"`
+	}{v.App, v.Version, v.CurrentVersion, v.AlternateVersion, errMsg})
+}
+
+// MarshalJSON encodes AppAudit with stable, lowercase field names, reducing
+// Error to its message so AppAudit remains marshalable even when Error is set.
+func (a AppAudit) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if a.Error != nil {
+		errMsg = a.Error.Error()
+	}
+	return json.Marshal(struct {
+		App       string `json:"app"`
+		Version   string `json:"version,omitempty"`
+		Status    string `json:"status,omitempty"`
+		Installed bool   `json:"installed"`
+		Error     string `json:"error,omitempty"`
+	}{a.App, a.Version, a.Status, a.Installed, errMsg})
+}
+
+// ClassicError is returned when NinitePro.exe writes to stderr or exits with
+// an error, in place of the prior naive errors.New(string(stderr)).
+type ClassicError struct {
+	Args     []string // the arguments NinitePro.exe was invoked with
+	ExitCode int
+	Stderr   []byte
+	Wrapped  error // the underlying error from exec, if any
+}
+
+func (e *ClassicError) Error() string {
+	if len(e.Stderr) > 0 {
+		return fmt.Sprintf("niniteclassic: %s", strings.TrimSpace(string(e.Stderr)))
+	}
+	if e.Wrapped != nil {
+		return fmt.Sprintf("niniteclassic: %s", e.Wrapped.Error())
+	}
+	return fmt.Sprintf("niniteclassic: exited with code %d", e.ExitCode)
+}
+
+// Unwrap returns the underlying error, if any, so that errors.Is/As work
+// against the exec-level failure that produced this ClassicError.
+func (e *ClassicError) Unwrap() error {
+	return e.Wrapped
+}
+
+// newClassicError builds a ClassicError from a finished cmd, its captured
+// stderr, and the error (if any) returned by cmd.Wait.
+func newClassicError(cmd *exec.Cmd, stderr []byte, waitErr error) *ClassicError {
+	ce := &ClassicError{
+		Args:    cmd.Args,
+		Stderr:  stderr,
+		Wrapped: waitErr,
+	}
+	if cmd.ProcessState != nil {
+		ce.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	return ce
+}
+
+// ErrUnknownVersion is returned when a version passed to Prefer does not
+// appear in the app's available versions, as reported by List.
+type ErrUnknownVersion struct {
+	App     string
+	Version string
+}
+
+func (e *ErrUnknownVersion) Error() string {
+	return fmt.Sprintf("niniteclassic: %q is not a known version of %q", e.Version, e.App)
+}
+
+// Runner is the subset of Classic's verb methods that cmd/ninite (and other
+// callers that want to swap in a mock backend for tests) depend on.
+type Runner interface {
+	InstallContext(ctx context.Context, statusChan chan<- Status) error
+	UpdateOnlyContext(ctx context.Context, statusChan chan<- Status) error
+	UninstallContext(ctx context.Context, statusChan chan<- Status) error
+	FreezeContext(ctx context.Context, statusChan chan<- Status, output string, locales ...string) error
+	ListContext(ctx context.Context, versionChan chan<- AppVersion) error
+	AuditContext(ctx context.Context, auditChan chan<- AppAudit) error
+}
+
+var _ Runner = Classic{}
+
+// validatePreferences checks every app/version pair set via Prefer against
+// the versions List reports as available, returning an *ErrUnknownVersion
+// for the first one that can't be satisfied. "latest" and "current" are
+// always accepted, since they track Ninite's own default rather than naming
+// a specific version.
+func (c Classic) validatePreferences(ctx context.Context) error {
+	if len(c.preferences) == 0 {
+		return nil
+	}
+
+	lister := Classic{path: c.path, locale: c.locale}
+
+	versionChan := make(chan AppVersion)
+	listErr := make(chan error, 1)
+	go func() {
+		listErr <- lister.ListContext(ctx, versionChan)
+	}()
+
+	available := map[string]map[string]bool{}
+	for v := range versionChan {
+		if v.Error != nil {
+			continue
+		}
+		if available[v.App] == nil {
+			available[v.App] = map[string]bool{}
+		}
+		available[v.App][v.Version] = true
+	}
+	if err := <-listErr; err != nil {
+		return err
+	}
+
+	for _, app := range c.sortedPreferredApps() {
+		version := c.preferences[app]
+		if version == "latest" || version == "current" {
+			continue
+		}
+		if !available[app][version] {
+			return &ErrUnknownVersion{App: app, Version: version}
+		}
+	}
+
+	return nil
+}
+
+// mergedExcludedApps combines excludedApps with held, the apps pinned via
+// Hold, without duplicating an app present in both.
+func (c Classic) mergedExcludedApps() []string {
+	if len(c.held) == 0 {
+		return c.excludedApps
+	}
+
+	seen := make(map[string]bool, len(c.excludedApps))
+	merged := append([]string{}, c.excludedApps...)
+	for _, app := range merged {
+		seen[app] = true
+	}
+	for _, app := range c.held {
+		if !seen[app] {
+			merged = append(merged, app)
+			seen[app] = true
+		}
+	}
+	return merged
+}
+
+// sortedPreferredApps returns the apps named in c.preferences in a stable
+// order, so that composeArgs produces deterministic /prefer argument groups.
+func (c Classic) sortedPreferredApps() []string {
+	apps := make([]string, 0, len(c.preferences))
+	for app := range c.preferences {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	return apps
+}
 
 func (c Classic) composeArgs() []string {
 	args := []string{"/silent", "."}
@@ -101,8 +291,12 @@ func (c Classic) composeArgs() []string {
 		args = append(append(args, "/select"), c.selectedApps...)
 	}
 
-	if len(c.excludedApps) > 0 {
-		args = append(append(args, "/exclude"), c.excludedApps...)
+	if excluded := c.mergedExcludedApps(); len(excluded) > 0 {
+		args = append(append(args, "/exclude"), excluded...)
+	}
+
+	for _, app := range c.sortedPreferredApps() {
+		args = append(args, "/prefer", app, c.preferences[app])
 	}
 
 	if len(c.remote) > 0 {
@@ -144,6 +338,10 @@ func (c Classic) composeArgs() []string {
 		args = append(args, "/updateonly")
 	}
 
+	if c.uninstall {
+		args = append(args, "/uninstall")
+	}
+
 	if c.freeze.outputFilename != "" {
 		args = append(args, "/freeze")
 		if len(c.freeze.locales) > 0 {
@@ -160,8 +358,16 @@ func (c Classic) composeArgs() []string {
 }
 
 func (c Classic) start() (*exec.Cmd, io.ReadCloser, io.ReadCloser, error) {
+	return c.startContext(context.Background())
+}
+
+func (c Classic) startContext(ctx context.Context) (*exec.Cmd, io.ReadCloser, io.ReadCloser, error) {
+	if err := c.validatePreferences(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
 	args := c.composeArgs()
-	cmd := exec.Command(c.path, args...)
+	cmd := exec.CommandContext(ctx, c.path, args...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -229,12 +435,22 @@ func (c Classic) Exclude(apps ...string) Classic {
 	return c
 }
 
-// Prefer sets version preferences per Ninite-managed app.
-func (c Classic) Prefer() Classic {
-	if true {
-		panic("Unimplemented") // TODO: implement this
-	}
-	return Classic{}
+// Prefer sets version preferences per Ninite-managed app. versions maps an
+// app name to either a specific version string, or "latest"/"current" to
+// track Ninite's own default behavior. Preferred versions are validated
+// against List's output before each verb runs, returning ErrUnknownVersion
+// if a requested version isn't available.
+func (c Classic) Prefer(versions map[string]string) Classic {
+	c.preferences = versions
+	return c
+}
+
+// Hold pins apps to their currently installed version, mirroring the "hold"
+// pattern from Scoop-derived package managers: repeated UpdateOnly calls
+// will skip them, the same as if they had been passed to Exclude.
+func (c Classic) Hold(apps ...string) Classic {
+	c.held = apps
+	return c
 }
 
 // Remote identifies remote computers to manage.  This can be either machine addresses or filenames prefixed with `file:`.
@@ -289,249 +505,128 @@ func (c Classic) CleanCache() Classic {
 //
 // Verbs
 
-// UpdateOnly performs an update on software that is already installed and does not cause any new software to become installed.
-func (c Classic) UpdateOnly(statusChan chan<- Status) error {
-	c.updateOnly = true
-
-	cmd, stdout, stderr, err := c.start()
-	if err != nil {
-		return err
-	}
-	defer cmd.Wait() // ???: is this necessary? it is possible to return before cmd.Wait is run without this.
-
-	b := bufio.NewReader(stdout)
-	for {
-		line, err := b.ReadString('\n')
-		if err == io.EOF {
-			close(statusChan)
-			break
-		} else if err != nil {
-			return err
-		}
-
-		if m := statusMatch.FindStringSubmatch(line); len(m) > 0 {
-			statusChan <- Status{
-				App:    m[1],
-				Status: m[2],
-				Reason: m[3],
-			}
-		}
-	}
-
-	var stderrResult error
-	if se, err := ioutil.ReadAll(stderr); err == nil {
-		if len(se) > 0 {
-			stderrResult = errors.New(string(se)) // FIXME: this is naive
-		}
-	}
+// Install installs selected Ninite-managed apps, updating any that are
+// already installed.
+func (c Classic) Install(statusChan chan<- Status) error {
+	return c.InstallContext(context.Background(), statusChan)
+}
 
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
+// InstallContext is Install with a context.Context that, when canceled,
+// terminates the underlying NinitePro.exe process.
+func (c Classic) InstallContext(ctx context.Context, statusChan chan<- Status) error {
+	return c.runStatus(ctx, statusLine, statusChan)
+}
 
-	if stderrResult != nil { // if all is apparently well but there was text in stderr, use that as an error
-		return stderrResult
-	}
+// UpdateOnly performs an update on software that is already installed and does not cause any new software to become installed.
+func (c Classic) UpdateOnly(statusChan chan<- Status) error {
+	return c.UpdateOnlyContext(context.Background(), statusChan)
+}
 
-	return nil
+// UpdateOnlyContext is UpdateOnly with a context.Context that, when canceled,
+// terminates the underlying NinitePro.exe process.
+func (c Classic) UpdateOnlyContext(ctx context.Context, statusChan chan<- Status) error {
+	c.updateOnly = true
+	return c.runStatus(ctx, statusLine, statusChan)
 }
 
 // Uninstall performs an uninstall on selected Ninite-managed apps.
 func (c Classic) Uninstall(statusChan chan<- Status) error {
-	c.uninstall = true
-
-	cmd, stdout, stderr, err := c.start()
-	if err != nil {
-		return err
-	}
-	defer cmd.Wait() // ???: is this necessary? it is possible to return before cmd.Wait is run without this.
-
-	b := bufio.NewReader(stdout)
-	for {
-		line, err := b.ReadString('\n')
-		if err == io.EOF {
-			close(statusChan)
-			break
-		} else if err != nil {
-			return err
-		}
-
-		if m := statusMatch.FindStringSubmatch(line); len(m) > 0 {
-			statusChan <- Status{
-				App:    m[1],
-				Status: m[2],
-				Reason: m[3],
-			}
-		}
-	}
-
-	var stderrResult error
-	if se, err := ioutil.ReadAll(stderr); err == nil {
-		if len(se) > 0 {
-			stderrResult = errors.New(string(se)) // FIXME: this is naive
-		}
-	}
-
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
-
-	if stderrResult != nil { // if all is apparently well but there was text in stderr, use that as an error
-		return stderrResult
-	}
+	return c.UninstallContext(context.Background(), statusChan)
+}
 
-	return nil
+// UninstallContext is Uninstall with a context.Context that, when canceled,
+// terminates the underlying NinitePro.exe process.
+func (c Classic) UninstallContext(ctx context.Context, statusChan chan<- Status) error {
+	c.uninstall = true
+	return c.runStatus(ctx, statusLine, statusChan)
 }
 
 // Freeze creates an offline installer for the selected Ninite-managed apps.
 func (c Classic) Freeze(statusChan chan<- Status, output string, locales ...string) error {
+	return c.FreezeContext(context.Background(), statusChan, output, locales...)
+}
+
+// FreezeContext is Freeze with a context.Context that, when canceled,
+// terminates the underlying NinitePro.exe process.
+func (c Classic) FreezeContext(ctx context.Context, statusChan chan<- Status, output string, locales ...string) error {
 	c.freeze.outputFilename = output
 	c.freeze.locales = locales
+	return c.runStatus(ctx, freezeLine, statusChan)
+}
 
-	cmd, stdout, stderr, err := c.start()
+// runStatus is the shared implementation behind every verb whose output is
+// a stream of Status values (Install, UpdateOnly, Uninstall, Freeze).
+func (c Classic) runStatus(ctx context.Context, kind lineKind, statusChan chan<- Status) error {
+	p, err := newParser(kind, c.locale)
 	if err != nil {
+		close(statusChan)
 		return err
 	}
-	defer cmd.Wait() // ???: is this necessary? it is possible to return before cmd.Wait is run without this.
-
-	b := bufio.NewReader(stdout)
-	for {
-		line, err := b.ReadString('\n')
-		if err == io.EOF {
-			close(statusChan)
-			break
-		} else if err != nil {
-			return err
-		}
-
-		if m := statusMatch.FindStringSubmatch(line); len(m) > 0 {
-			statusChan <- Status{
-				App:     m[1],
-				Version: m[2],
-			}
-		}
-	}
 
-	var stderrResult error
-	if se, err := ioutil.ReadAll(stderr); err == nil {
-		if len(se) > 0 {
-			stderrResult = errors.New(string(se)) // FIXME: this is naive
-		}
-	}
+	raw := make(chan any)
+	errChan := make(chan error, 1)
+	go func() { errChan <- c.runStreaming(ctx, p, raw) }()
 
-	if err := cmd.Wait(); err != nil {
-		return err
+	for item := range raw {
+		statusChan <- item.(Status)
 	}
+	close(statusChan)
 
-	if stderrResult != nil { // if all is apparently well but there was text in stderr, use that as an error
-		return stderrResult
-	}
-
-	return nil
+	return <-errChan
 }
 
 // List lists all (or selected) Ninite-managed apps available for install, including their versions.
 func (c Classic) List(versionChan chan<- AppVersion) error {
+	return c.ListContext(context.Background(), versionChan)
+}
+
+// ListContext is List with a context.Context that, when canceled, terminates
+// the underlying NinitePro.exe process.
+func (c Classic) ListContext(ctx context.Context, versionChan chan<- AppVersion) error {
 	c.list = true
 
-	cmd, stdout, stderr, err := c.start()
+	p, err := newParser(versionLine, c.locale)
 	if err != nil {
+		close(versionChan)
 		return err
 	}
-	defer cmd.Wait() // ???: is this necessary? it is possible to return before cmd.Wait is run without this.
-
-	b := bufio.NewReader(stdout)
-	for {
-		line, err := b.ReadString('\n')
-		if err == io.EOF {
-			close(versionChan)
-			break
-		} else if err != nil {
-			return err
-		}
-
-		if m := versionMatch.FindStringSubmatch(line); len(m) > 0 {
-			var currentVersion, alternateVersion bool
-			if m[2] == "*" {
-				currentVersion = true
-			} else if m[2] == "(" {
-				alternateVersion = true
-			}
-			versionChan <- AppVersion{
-				App:              m[1],
-				Version:          m[3],
-				CurrentVersion:   currentVersion,
-				AlternateVersion: alternateVersion,
-			}
-		}
-	}
 
-	var stderrResult error
-	if se, err := ioutil.ReadAll(stderr); err == nil {
-		if len(se) > 0 {
-			stderrResult = errors.New(string(se)) // FIXME: this is naive
-		}
-	}
-
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
+	raw := make(chan any)
+	errChan := make(chan error, 1)
+	go func() { errChan <- c.runStreaming(ctx, p, raw) }()
 
-	if stderrResult != nil { // if all is apparently well but there was text in stderr, use that as an error
-		return stderrResult
+	for item := range raw {
+		versionChan <- item.(AppVersion)
 	}
+	close(versionChan)
 
-	return nil
+	return <-errChan
 }
 
 // Audit lists all (or selected) Ninite-managed apps, including their versions and whether they are installed.
 func (c Classic) Audit(auditChan chan<- AppAudit) error {
+	return c.AuditContext(context.Background(), auditChan)
+}
+
+// AuditContext is Audit with a context.Context that, when canceled,
+// terminates the underlying NinitePro.exe process.
+func (c Classic) AuditContext(ctx context.Context, auditChan chan<- AppAudit) error {
 	c.list = true
 
-	cmd, stdout, stderr, err := c.start()
+	p, err := newParser(auditLine, c.locale)
 	if err != nil {
+		close(auditChan)
 		return err
 	}
-	defer cmd.Wait() // ???: is this necessary? it is possible to return before cmd.Wait is run without this.
 
-	b := bufio.NewReader(stdout)
-	for {
-		line, err := b.ReadString('\n')
-		if err == io.EOF {
-			close(auditChan)
-			break
-		} else if err != nil {
-			return err
-		}
+	raw := make(chan any)
+	errChan := make(chan error, 1)
+	go func() { errChan <- c.runStreaming(ctx, p, raw) }()
 
-		if m := auditMatch.FindStringSubmatch(line); len(m) > 0 {
-			var installed bool
-			if len(m[3]) > 0 {
-				installed = true
-			}
-			auditChan <- AppAudit{
-				App:       m[1],
-				Status:    m[2],
-				Version:   m[3],
-				Installed: installed,
-			}
-		}
+	for item := range raw {
+		auditChan <- item.(AppAudit)
 	}
+	close(auditChan)
 
-	var stderrResult error
-	if se, err := ioutil.ReadAll(stderr); err == nil {
-		if len(se) > 0 {
-			stderrResult = errors.New(string(se)) // FIXME: this is naive
-		}
-	}
-
-	if err := cmd.Wait(); err != nil {
-		return err
-	}
-
-	if stderrResult != nil { // if all is apparently well but there was text in stderr, use that as an error
-		return stderrResult
-	}
-
-	return nil
+	return <-errChan
 }
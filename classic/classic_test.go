@@ -0,0 +1,115 @@
+package niniteclassic
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMergedExcludedApps(t *testing.T) {
+	tests := []struct {
+		name     string
+		excluded []string
+		held     []string
+		want     []string
+	}{
+		{"neither set", nil, nil, nil},
+		{"only excluded", []string{"Skype"}, nil, []string{"Skype"}},
+		{"only held", nil, []string{"Skype"}, []string{"Skype"}},
+		{"disjoint", []string{"Skype"}, []string{"Zoom"}, []string{"Skype", "Zoom"}},
+		{"overlap is deduplicated", []string{"Skype", "Zoom"}, []string{"Zoom", "Slack"}, []string{"Skype", "Zoom", "Slack"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Classic{excludedApps: tt.excluded, held: tt.held}
+			got := c.mergedExcludedApps()
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i, app := range tt.want {
+				if got[i] != app {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+// fakeNinitePro writes an executable shell script that echoes fixed-width
+// "/list versions" output, standing in for NinitePro.exe in tests that need
+// a real process to run List/validatePreferences against.
+func fakeNinitePro(t *testing.T) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("fakeNinitePro requires a POSIX shell")
+	}
+
+	path := filepath.Join(t.TempDir(), "NinitePro.sh")
+	script := "#!/bin/sh\ncat <<'EOF'\n" +
+		"7-Zip            : *19.00\n" +
+		"Google Chrome    : 108.0.5359.125\n" +
+		"Mozilla Firefox  : (107.0)\n" +
+		"EOF\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake NinitePro: %v", err)
+	}
+	return path
+}
+
+func TestValidatePreferencesAcceptsKnownVersion(t *testing.T) {
+	c := Classic{path: fakeNinitePro(t)}.Prefer(map[string]string{"7-Zip": "19.00"})
+
+	if err := c.validatePreferences(context.Background()); err != nil {
+		t.Errorf("validatePreferences: %v", err)
+	}
+}
+
+func TestValidatePreferencesRejectsUnknownVersion(t *testing.T) {
+	c := Classic{path: fakeNinitePro(t)}.Prefer(map[string]string{"7-Zip": "99.99"})
+
+	err := c.validatePreferences(context.Background())
+	var unknownVersion *ErrUnknownVersion
+	if !errors.As(err, &unknownVersion) {
+		t.Fatalf("validatePreferences: got %v, want *ErrUnknownVersion", err)
+	}
+	if unknownVersion.App != "7-Zip" || unknownVersion.Version != "99.99" {
+		t.Errorf("got %+v", unknownVersion)
+	}
+}
+
+func TestValidatePreferencesAllowsLatestAndCurrentWithoutLookup(t *testing.T) {
+	c := Classic{path: fakeNinitePro(t)}.Prefer(map[string]string{
+		"Google Chrome":  "latest",
+		"Does Not Exist": "current",
+	})
+
+	if err := c.validatePreferences(context.Background()); err != nil {
+		t.Errorf("validatePreferences: %v", err)
+	}
+}
+
+func TestComposeArgsUninstall(t *testing.T) {
+	var c Classic
+	c.uninstall = true
+	c = c.Select("Firefox")
+
+	args := c.composeArgs()
+
+	found := false
+	for _, arg := range args {
+		if arg == "/uninstall" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("composeArgs() = %v, want it to include /uninstall", args)
+	}
+}
@@ -0,0 +1,176 @@
+package niniteclassic
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// Locale identifies the language NinitePro.exe is expected to write its
+// output in. It corresponds to the locale string passed to Locale/Freeze.
+type Locale string
+
+// DefaultLocale is used whenever a Classic has no locale set.
+const DefaultLocale Locale = "en"
+
+// lineRules is the set of line-matching regexps for one locale.
+type lineRules struct {
+	status  *regexp.Regexp
+	version *regexp.Regexp
+	audit   *regexp.Regexp
+}
+
+// localeTable maps a Locale to the line-matching rules for it. Only English
+// output is recognized today; additional locales can be added here as their
+// output formats are captured (see testdata/).
+var localeTable = map[Locale]lineRules{
+	DefaultLocale: {
+		status:  regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<status>[^\r\n\(\)]+)(?:\s+\((?P<reason>.+)\))?$`),
+		version: regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<type>[\*\(])?(?P<version>[^\r\n\(\)]+)\)?$`),
+		audit:   regexp.MustCompile(`^\s*(?P<app>[^:\r\n]+)\s+:\s+(?P<status>[^\r\n\(\)\-]+)(?:\s+-\s+(?P<version>.+))?$`),
+	},
+}
+
+// lineKind identifies which of NinitePro.exe's output shapes a parser reads,
+// since /updateonly, /uninstall and plain install share the status line
+// shape but report it through different fields than /freeze does.
+type lineKind int
+
+// Recognized line kinds.
+const (
+	statusLine lineKind = iota
+	freezeLine
+	versionLine
+	auditLine
+)
+
+// parser turns one line of NinitePro.exe output into a Status, AppVersion,
+// or AppAudit (matching lineKind), using the rules for a given Locale.
+type parser struct {
+	kind  lineKind
+	rules lineRules
+}
+
+// newParser returns a parser for kind using locale's rules, falling back to
+// DefaultLocale when locale is empty.
+func newParser(kind lineKind, locale string) (*parser, error) {
+	loc := Locale(locale)
+	if loc == "" {
+		loc = DefaultLocale
+	}
+
+	rules, ok := localeTable[loc]
+	if !ok {
+		return nil, fmt.Errorf("niniteclassic: no parser rules for locale %q", locale)
+	}
+
+	return &parser{kind: kind, rules: rules}, nil
+}
+
+// parse matches line against the rule for p.kind, returning a Status,
+// AppVersion, or AppAudit on success, an equivalent value with Error set if
+// line is non-blank but unparseable, or nil for a blank line.
+func (p *parser) parse(line string) any {
+	line = strings.TrimRight(line, "\r\n")
+
+	switch p.kind {
+	case statusLine:
+		if m := p.rules.status.FindStringSubmatch(line); len(m) > 0 {
+			return Status{App: strings.TrimSpace(m[1]), Status: strings.TrimSpace(m[2]), Reason: m[3]}
+		}
+	case freezeLine:
+		if m := p.rules.status.FindStringSubmatch(line); len(m) > 0 {
+			return Status{App: strings.TrimSpace(m[1]), Version: strings.TrimSpace(m[2])}
+		}
+	case versionLine:
+		if m := p.rules.version.FindStringSubmatch(line); len(m) > 0 {
+			return AppVersion{
+				App:              strings.TrimSpace(m[1]),
+				Version:          strings.TrimSpace(m[3]),
+				CurrentVersion:   m[2] == "*",
+				AlternateVersion: m[2] == "(",
+			}
+		}
+	case auditLine:
+		if m := p.rules.audit.FindStringSubmatch(line); len(m) > 0 {
+			return AppAudit{App: strings.TrimSpace(m[1]), Status: strings.TrimSpace(m[2]), Version: m[3], Installed: len(m[3]) > 0}
+		}
+	}
+
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+	return p.unparsed(line)
+}
+
+// unparsed returns the Error-carrying variant of p.kind's result type for a
+// line that didn't match any rule.
+func (p *parser) unparsed(line string) any {
+	err := fmt.Errorf("niniteclassic: unparsed line: %q", line)
+	switch p.kind {
+	case versionLine:
+		return AppVersion{Error: err}
+	case auditLine:
+		return AppAudit{Error: err}
+	default:
+		return Status{Error: err}
+	}
+}
+
+// readLines reads r line by line via bufio.Reader.ReadString, which returns
+// each line with its trailing delimiter still attached, and a final line
+// with no trailing delimiter alongside io.EOF. It forwards every non-blank
+// parsed result to out (parse itself strips the delimiter), including that
+// last undelimited line, and returns nil on a clean EOF.
+func readLines(r io.Reader, p *parser, out chan<- any) error {
+	b := bufio.NewReader(r)
+	for {
+		line, err := b.ReadString('\n')
+		if line != "" {
+			if item := p.parse(line); item != nil {
+				out <- item
+			}
+		}
+
+		if err == nil {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+}
+
+// runStreaming starts c, reads its stdout line by line through p, and sends
+// each parsed result to out. It owns the entire lifecycle of the command:
+// draining stderr, waiting for exit, and closing out exactly once whether
+// the stream ends in EOF or in a read error - fixing the previous bug where
+// a non-EOF ReadString error left the result channel (and its reader)
+// hanging forever.
+func (c Classic) runStreaming(ctx context.Context, p *parser, out chan<- any) error {
+	cmd, stdout, stderr, err := c.startContext(ctx)
+	if err != nil {
+		close(out)
+		return err
+	}
+
+	readErr := readLines(stdout, p, out)
+	close(out)
+
+	se, _ := ioutil.ReadAll(stderr)
+	waitErr := cmd.Wait()
+
+	if readErr != nil {
+		return readErr
+	}
+	if waitErr != nil || len(se) > 0 {
+		return newClassicError(cmd, se, waitErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package niniteclassic
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readTestdataLines(t *testing.T, name string) []string {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", "en", name))
+	if err != nil {
+		t.Fatalf("open testdata: %v", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("scan testdata: %v", err)
+	}
+	return lines
+}
+
+func TestParserStatusLine(t *testing.T) {
+	p, err := newParser(statusLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	lines := readTestdataLines(t, "status.txt")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 fixture lines, got %d", len(lines))
+	}
+
+	want := []Status{
+		{App: "7-Zip", Status: "Up to date"},
+		{App: "Google Chrome", Status: "Installed"},
+		{App: "Mozilla Firefox", Status: "Failed", Reason: "Download error"},
+		{App: "Adobe Reader", Status: "Skipped", Reason: "Not selected"},
+	}
+
+	for i, line := range lines {
+		got, ok := p.parse(line).(Status)
+		if !ok {
+			t.Fatalf("line %q: did not parse as Status", line)
+		}
+		got.App = strings.TrimSpace(got.App)
+		got.Status = strings.TrimSpace(got.Status)
+		if got != want[i] {
+			t.Errorf("line %q: got %+v, want %+v", line, got, want[i])
+		}
+	}
+}
+
+func TestParserVersionLine(t *testing.T) {
+	p, err := newParser(versionLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	lines := readTestdataLines(t, "version.txt")
+	want := []AppVersion{
+		{App: "7-Zip", Version: "19.00", CurrentVersion: true},
+		{App: "Google Chrome", Version: "108.0.5359.125"},
+		{App: "Mozilla Firefox", Version: "107.0", AlternateVersion: true},
+	}
+
+	for i, line := range lines {
+		got, ok := p.parse(line).(AppVersion)
+		if !ok {
+			t.Fatalf("line %q: did not parse as AppVersion", line)
+		}
+		got.App = strings.TrimSpace(got.App)
+		if got != want[i] {
+			t.Errorf("line %q: got %+v, want %+v", line, got, want[i])
+		}
+	}
+}
+
+func TestParserAuditLine(t *testing.T) {
+	p, err := newParser(auditLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	lines := readTestdataLines(t, "audit.txt")
+	want := []AppAudit{
+		{App: "7-Zip", Status: "Installed", Version: "19.00", Installed: true},
+		{App: "Google Chrome", Status: "Installed", Version: "108.0.5359.125", Installed: true},
+		{App: "Mozilla Firefox", Status: "Not Installed"},
+	}
+
+	for i, line := range lines {
+		got, ok := p.parse(line).(AppAudit)
+		if !ok {
+			t.Fatalf("line %q: did not parse as AppAudit", line)
+		}
+		got.App = strings.TrimSpace(got.App)
+		got.Status = strings.TrimSpace(got.Status)
+		if got != want[i] {
+			t.Errorf("line %q: got %+v, want %+v", line, got, want[i])
+		}
+	}
+}
+
+func TestParserUnparsedLineSetsError(t *testing.T) {
+	p, err := newParser(auditLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	got, ok := p.parse("not a line NinitePro would ever print").(AppAudit)
+	if !ok {
+		t.Fatalf("expected an AppAudit result")
+	}
+	if got.Error == nil {
+		t.Errorf("expected Error to be set for an unparseable line")
+	}
+}
+
+func TestParserBlankLineIsIgnored(t *testing.T) {
+	p, err := newParser(statusLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	if got := p.parse("   \n"); got != nil {
+		t.Errorf("expected nil for a blank line, got %#v", got)
+	}
+}
+
+func TestNewParserUnknownLocale(t *testing.T) {
+	if _, err := newParser(statusLine, "xx-XX"); err == nil {
+		t.Fatal("expected an error for an unsupported locale")
+	}
+}
+
+// TestParseStripsLineDelimiter guards against a regression where the '$' in
+// each locale's regexes, which only matches true end-of-string, silently
+// failed to match every line because bufio.Reader.ReadString('\n') returns
+// each line with its delimiter still attached.
+func TestParseStripsLineDelimiter(t *testing.T) {
+	p, err := newParser(statusLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	for _, line := range []string{
+		"7-Zip            : Up to date\n",
+		"7-Zip            : Up to date\r\n",
+	} {
+		got, ok := p.parse(line).(Status)
+		if !ok {
+			t.Fatalf("line %q: did not parse as Status", line)
+		}
+		if got.Error != nil {
+			t.Errorf("line %q: got parse error: %v", line, got.Error)
+		}
+		if strings.TrimSpace(got.Status) != "Up to date" {
+			t.Errorf("line %q: got Status %q, want \"Up to date\"", line, got.Status)
+		}
+	}
+}
+
+// TestReadLinesParsesFinalLineWithoutDelimiter guards against a regression
+// where the final line of output - returned by ReadString alongside io.EOF
+// when it has no trailing '\n' - was discarded before ever reaching parse.
+func TestReadLinesParsesFinalLineWithoutDelimiter(t *testing.T) {
+	p, err := newParser(statusLine, "")
+	if err != nil {
+		t.Fatalf("newParser: %v", err)
+	}
+
+	r := strings.NewReader("7-Zip            : Up to date\nGoogle Chrome    : Installed")
+
+	out := make(chan any, 2)
+	if err := readLines(r, p, out); err != nil {
+		t.Fatalf("readLines: %v", err)
+	}
+	close(out)
+
+	var got []Status
+	for item := range out {
+		s, ok := item.(Status)
+		if !ok {
+			t.Fatalf("item %#v is not a Status", item)
+		}
+		got = append(got, s)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 Status values, got %d: %+v", len(got), got)
+	}
+	if strings.TrimSpace(got[0].App) != "7-Zip" || strings.TrimSpace(got[1].App) != "Google Chrome" {
+		t.Errorf("got %+v", got)
+	}
+	if got[1].Error != nil {
+		t.Errorf("final undelimited line was not parsed: %v", got[1].Error)
+	}
+}
@@ -0,0 +1,171 @@
+package niniteclassic
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Refer to https://ninite.com/help/features/switches.html for any missing details.
+
+const (
+	remoteFanOutRetries = 3
+	remoteFanOutBackoff = 2 * time.Second
+)
+
+// HostStatus pairs a Status with the remote host it was reported for, as
+// produced by RemoteFanOut.
+type HostStatus struct {
+	Host   string
+	Status Status
+}
+
+// RemoteFanOut runs UpdateOnly concurrently against each of machines,
+// fanning out up to parallel simultaneous NinitePro.exe invocations (each
+// targeting a single host via /remote) and merging their Status output into
+// the returned channel, tagged with the originating host. This avoids the
+// bottleneck of the built-in /remote mode, which visits its machine list
+// serially. Entries in machines may be filenames prefixed with "file:",
+// which are expanded to one host per line. The returned channel is closed
+// once every host has completed.
+func (c Classic) RemoteFanOut(machines []string, parallel int) (<-chan HostStatus, error) {
+	return c.RemoteFanOutContext(context.Background(), machines, parallel)
+}
+
+// RemoteFanOutContext is RemoteFanOut with a context.Context that, when
+// canceled, stops launching new hosts and terminates in-flight ones.
+func (c Classic) RemoteFanOutContext(ctx context.Context, machines []string, parallel int) (<-chan HostStatus, error) {
+	hosts, err := expandMachines(machines)
+	if err != nil {
+		return nil, err
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	out := make(chan HostStatus)
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	go func() {
+	launch:
+		for _, host := range hosts {
+			host := host
+			if ctx.Err() != nil {
+				break
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				break launch
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				runRemoteHost(ctx, c, host, out)
+			}()
+		}
+
+		wg.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// runRemoteHost runs UpdateOnly against a single host, retrying with
+// exponential backoff when the failure looks transient, and forwards every
+// Status (and the final error, if any) to out tagged with host. If ctx is
+// canceled while waiting to retry, it returns without forwarding that error.
+func runRemoteHost(ctx context.Context, c Classic, host string, out chan<- HostStatus) {
+	var err error
+	for attempt := 0; attempt <= remoteFanOutRetries; attempt++ {
+		statusChan := make(chan Status)
+		done := make(chan error, 1)
+
+		go func() {
+			done <- c.Remote(host).UpdateOnlyContext(ctx, statusChan)
+		}()
+
+		for status := range statusChan {
+			out <- HostStatus{Host: host, Status: status}
+		}
+		err = <-done
+
+		if err == nil || !isTransientRemoteError(err) || attempt == remoteFanOutRetries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(remoteFanOutBackoff << attempt):
+		}
+	}
+
+	if err != nil {
+		out <- HostStatus{Host: host, Status: Status{Error: err}}
+	}
+}
+
+// isTransientRemoteError reports whether err looks like a transient network
+// or WMI failure worth retrying, as opposed to a permanent configuration
+// error such as an unknown host or bad credentials.
+func isTransientRemoteError(err error) bool {
+	var ce *ClassicError
+	if !errors.As(err, &ce) {
+		return false
+	}
+
+	msg := strings.ToLower(string(ce.Stderr))
+	for _, pattern := range []string{"wmi", "rpc server is unavailable", "timed out", "timeout", "network path was not found", "connection"} {
+		if strings.Contains(msg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMachines expands any "file:"-prefixed entries in machines into one
+// host per non-empty, non-comment line of the named file, passing other
+// entries through unchanged.
+func expandMachines(machines []string) ([]string, error) {
+	var hosts []string
+	for _, m := range machines {
+		path, ok := strings.CutPrefix(m, "file:")
+		if !ok {
+			hosts = append(hosts, m)
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			hosts = append(hosts, line)
+		}
+		scanErr := scanner.Err()
+		closeErr := f.Close()
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		if closeErr != nil {
+			return nil, closeErr
+		}
+	}
+	return hosts, nil
+}
@@ -0,0 +1,59 @@
+package niniteclassic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandMachines(t *testing.T) {
+	dir := t.TempDir()
+	listPath := filepath.Join(dir, "hosts.txt")
+	contents := "host-a\n# a comment\n\n  host-b  \nhost-c\n"
+	if err := os.WriteFile(listPath, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write machine list: %v", err)
+	}
+
+	got, err := expandMachines([]string{"plain-host", "file:" + listPath, "another-host"})
+	if err != nil {
+		t.Fatalf("expandMachines: %v", err)
+	}
+
+	want := []string{"plain-host", "host-a", "host-b", "host-c", "another-host"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandMachinesMissingFile(t *testing.T) {
+	if _, err := expandMachines([]string{"file:" + filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Fatal("expected an error for a missing machine list file")
+	}
+}
+
+func TestIsTransientRemoteError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"non-ClassicError", errors.New("boom"), false},
+		{"wmi timeout", &ClassicError{Stderr: []byte("WMI call timed out")}, true},
+		{"rpc server unavailable", &ClassicError{Stderr: []byte("The RPC server is unavailable")}, true},
+		{"network path not found", &ClassicError{Stderr: []byte("The network path was not found")}, true},
+		{"connection refused", &ClassicError{Stderr: []byte("connection refused")}, true},
+		{"permanent failure", &ClassicError{Stderr: []byte("invalid credentials")}, false},
+		{"empty stderr", &ClassicError{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientRemoteError(tt.err); got != tt.want {
+				t.Errorf("isTransientRemoteError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Command ninite is a unified CLI over the classic package, replacing the
+// separate per-verb example programs. It exposes one subcommand per verb
+// and a --format flag so its output can be consumed as plain text or as
+// structured JSON/NDJSON for shell pipelines (e.g. `ninite audit
+// --format=ndjson | jq`).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	niniteclassic "github.com/emmaly/ninite/classic"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	verb := os.Args[1]
+	args := os.Args[2:]
+
+	var err error
+	switch verb {
+	case "install":
+		err = runStatusVerb(args, (niniteclassic.Runner).InstallContext)
+	case "update":
+		err = runStatusVerb(args, (niniteclassic.Runner).UpdateOnlyContext)
+	case "uninstall":
+		err = runStatusVerb(args, (niniteclassic.Runner).UninstallContext)
+	case "freeze":
+		err = runFreeze(args)
+	case "list":
+		err = runList(args)
+	case "audit":
+		err = runAudit(args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ninite <install|update|uninstall|freeze|list|audit> [flags]")
+}
+
+// commonFlags holds the flags shared by every subcommand.
+type commonFlags struct {
+	path    *string
+	format  *string
+	selects *string
+	exclude *string
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *commonFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := &commonFlags{
+		path:    fs.String("path", ".", "path to NinitePro.exe, or its containing directory"),
+		format:  fs.String("format", "text", "output format: text, json, or ndjson"),
+		selects: fs.String("select", "", "comma-separated apps to select"),
+		exclude: fs.String("exclude", "", "comma-separated apps to exclude"),
+	}
+	return fs, cf
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func (cf *commonFlags) newClassic() (niniteclassic.Classic, error) {
+	nc, err := niniteclassic.NewClassic(*cf.path)
+	if err != nil {
+		return niniteclassic.Classic{}, err
+	}
+	if apps := splitList(*cf.selects); len(apps) > 0 {
+		nc = nc.Select(apps...)
+	}
+	if apps := splitList(*cf.exclude); len(apps) > 0 {
+		nc = nc.Exclude(apps...)
+	}
+	return nc, nil
+}
+
+// runStatusVerb drives any verb whose channel type is Status (install,
+// update, uninstall), differing only in which Runner method runs it.
+func runStatusVerb(args []string, run func(niniteclassic.Runner, context.Context, chan<- niniteclassic.Status) error) error {
+	fs, cf := newFlagSet("ninite")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	nc, err := cf.newClassic()
+	if err != nil {
+		return err
+	}
+
+	statusChan := make(chan niniteclassic.Status)
+	errChan := make(chan error, 1)
+	go func() { errChan <- run(nc, context.Background(), statusChan) }()
+
+	if err := drain(*cf.format, statusChan, func(s niniteclassic.Status) string {
+		if s.Error != nil {
+			return fmt.Sprintf("[error] %s", s.Error)
+		}
+		return fmt.Sprintf("[%s]\n\tStatus: %s\n\tReason: %s\n", s.App, s.Status, s.Reason)
+	}); err != nil {
+		return err
+	}
+
+	return <-errChan
+}
+
+func runFreeze(args []string) error {
+	fs, cf := newFlagSet("ninite freeze")
+	output := fs.String("output", "", "offline installer output filename")
+	locales := fs.String("locales", "", "comma-separated locales to include")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return fmt.Errorf("ninite freeze: --output is required")
+	}
+
+	nc, err := cf.newClassic()
+	if err != nil {
+		return err
+	}
+
+	statusChan := make(chan niniteclassic.Status)
+	errChan := make(chan error, 1)
+	go func() { errChan <- nc.FreezeContext(context.Background(), statusChan, *output, splitList(*locales)...) }()
+
+	if err := drain(*cf.format, statusChan, func(s niniteclassic.Status) string {
+		if s.Error != nil {
+			return fmt.Sprintf("[error] %s", s.Error)
+		}
+		return fmt.Sprintf("[%s]\n\tVersion: %s\n", s.App, s.Version)
+	}); err != nil {
+		return err
+	}
+
+	return <-errChan
+}
+
+func runList(args []string) error {
+	fs, cf := newFlagSet("ninite list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	nc, err := cf.newClassic()
+	if err != nil {
+		return err
+	}
+
+	versionChan := make(chan niniteclassic.AppVersion)
+	errChan := make(chan error, 1)
+	go func() { errChan <- nc.ListContext(context.Background(), versionChan) }()
+
+	if err := drain(*cf.format, versionChan, func(v niniteclassic.AppVersion) string {
+		if v.Error != nil {
+			return fmt.Sprintf("[error] %s", v.Error)
+		}
+		return fmt.Sprintf("[%s]\n\tVersion: %s\n\tCurrentVersion: %t\n\tAlternateVersion: %t\n", v.App, v.Version, v.CurrentVersion, v.AlternateVersion)
+	}); err != nil {
+		return err
+	}
+
+	return <-errChan
+}
+
+func runAudit(args []string) error {
+	fs, cf := newFlagSet("ninite audit")
+	installedOnly := fs.Bool("installed", false, "show only installed apps (text format only)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	nc, err := cf.newClassic()
+	if err != nil {
+		return err
+	}
+
+	auditChan := make(chan niniteclassic.AppAudit)
+	errChan := make(chan error, 1)
+	go func() { errChan <- nc.AuditContext(context.Background(), auditChan) }()
+
+	if err := drain(*cf.format, auditChan, func(a niniteclassic.AppAudit) string {
+		if *installedOnly && !a.Installed {
+			return ""
+		}
+		if a.Error != nil {
+			return fmt.Sprintf("[error] %s", a.Error)
+		}
+		return fmt.Sprintf("[%s]\n\tStatus: %s\n\tVersion: %s\n\tInstalled: %t\n", a.App, a.Status, a.Version, a.Installed)
+	}); err != nil {
+		return err
+	}
+
+	return <-errChan
+}
+
+// drain reads every item off ch and prints it according to format: one JSON
+// object per line for "ndjson", a single JSON array for "json", or text
+// produces by textLine for anything else. Empty strings from textLine are
+// skipped, letting callers filter text output.
+func drain[T any](format string, ch <-chan T, textLine func(T) string) error {
+	enc := json.NewEncoder(os.Stdout)
+	collected := []T{}
+
+	for item := range ch {
+		switch format {
+		case "ndjson":
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		case "json":
+			collected = append(collected, item)
+		default:
+			if line := textLine(item); line != "" {
+				fmt.Println(line)
+			}
+		}
+	}
+
+	if format == "json" {
+		return enc.Encode(collected)
+	}
+	return nil
+}
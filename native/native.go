@@ -0,0 +1,338 @@
+// Package native installs Ninite-managed applications directly from their
+// vendor installers instead of shelling out to NinitePro.exe. It downloads
+// each installer, verifies its checksum, detects the installer type, and
+// invokes it with the appropriate silent-install switches. This lets the
+// module run on machines without a licensed NinitePro binary, and lets the
+// audit path run on Linux/macOS where no installer could be executed anyway.
+//
+// It emits the same Status and AppAudit types that the classic package uses
+// (github.com/emmaly/ninite/classic) so that callers can swap between the
+// two backends without changing their consuming code.
+package native
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	niniteclassic "github.com/emmaly/ninite/classic"
+)
+
+// InstallerType identifies which silent-install convention an installer uses.
+type InstallerType int
+
+// Recognized installer types.
+const (
+	Unknown InstallerType = iota
+	MSI
+	InnoSetup
+	NSIS
+	EXE
+)
+
+func (t InstallerType) String() string {
+	switch t {
+	case MSI:
+		return "MSI"
+	case InnoSetup:
+		return "InnoSetup"
+	case NSIS:
+		return "NSIS"
+	case EXE:
+		return "EXE"
+	default:
+		return "Unknown"
+	}
+}
+
+// AppManifest describes how to obtain and silently install one app.
+type AppManifest struct {
+	Name          string
+	Version       string
+	URL           string
+	SHA256        string
+	InstallerType InstallerType
+	// SilentArgs, when set, overrides the default silent-install switches
+	// for InstallerType. The literal string "{path}" is replaced with the
+	// path to the downloaded installer.
+	SilentArgs []string
+}
+
+// Manifest is a set of AppManifest entries keyed by app name.
+type Manifest map[string]AppManifest
+
+// installedApp is the on-disk record written by Install and read by Audit.
+type installedApp struct {
+	Version string `json:"version"`
+}
+
+// Native installs applications from a Manifest by directly invoking their
+// vendor installers.
+type Native struct {
+	manifest  Manifest
+	cachePath string
+	auditOnly bool
+}
+
+// NewNative returns a Native that installs from the given Manifest, caching
+// downloads and installed-state records under cachePath.
+func NewNative(manifest Manifest, cachePath string) (Native, error) {
+	if len(manifest) == 0 {
+		return Native{}, errors.New("native: manifest is empty")
+	}
+	if err := os.MkdirAll(cachePath, 0o755); err != nil {
+		return Native{}, err
+	}
+	return Native{manifest: manifest, cachePath: cachePath}, nil
+}
+
+// AuditOnly marks this instance as audit-only, refusing to run installers.
+// This is the mode intended for Linux/macOS, where vendor installers
+// targeted by this package cannot be executed.
+func (n Native) AuditOnly() Native {
+	n.auditOnly = true
+	return n
+}
+
+func (n Native) statePath() string {
+	return filepath.Join(n.cachePath, "installed.json")
+}
+
+func (n Native) loadState() (map[string]installedApp, error) {
+	state := map[string]installedApp{}
+	b, err := os.ReadFile(n.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (n Native) saveState(state map[string]installedApp) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(n.statePath(), b, 0o644)
+}
+
+func download(url, dest string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("native: download %s: unexpected status %s", url, resp.Status)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+func verifyChecksum(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	got := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("native: checksum mismatch: want %s, got %s", want, got)
+	}
+	return nil
+}
+
+// detectInstallerType identifies the installer type from a .msi extension or,
+// for any other file, its leading magic bytes: an OLE compound-file header
+// means MSI and a PE header means EXE. InnoSetup and NSIS are not told apart
+// from a generic EXE.
+func detectInstallerType(path string) (InstallerType, error) {
+	if strings.EqualFold(filepath.Ext(path), ".msi") {
+		return MSI, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Unknown, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return Unknown, err
+	}
+
+	switch {
+	case magic[0] == 0xD0 && magic[1] == 0xCF && magic[2] == 0x11 && magic[3] == 0xE0:
+		return MSI, nil // OLE compound file header, used by MSI
+	case magic[0] == 'M' && magic[1] == 'Z':
+		return EXE, nil // generic PE header; InnoSetup/NSIS are disambiguated by manifest
+	default:
+		return Unknown, fmt.Errorf("native: could not detect installer type for %s", path)
+	}
+}
+
+// silentArgsFor returns the program and arguments used to silently run path,
+// given its InstallerType.
+func silentArgsFor(it InstallerType, path string) (string, []string) {
+	switch it {
+	case MSI:
+		return "msiexec", []string{"/i", path, "/qn"}
+	case InnoSetup:
+		return path, []string{"/VERYSILENT", "/SUPPRESSMSGBOXES", "/NORESTART"}
+	case NSIS:
+		return path, []string{"/S"}
+	default:
+		return path, []string{"/S"}
+	}
+}
+
+func expandSilentArgs(args []string, path string) []string {
+	expanded := make([]string, len(args))
+	for i, a := range args {
+		expanded[i] = strings.ReplaceAll(a, "{path}", path)
+	}
+	return expanded
+}
+
+// Install downloads, verifies, and silently installs each named app, sending
+// a Status for each as it completes. statusChan is closed once all apps have
+// been processed.
+func (n Native) Install(apps []string, statusChan chan<- niniteclassic.Status) error {
+	if n.auditOnly {
+		close(statusChan)
+		return errors.New("native: Install is unavailable in audit-only mode")
+	}
+	if !SupportsInstall() {
+		close(statusChan)
+		return fmt.Errorf("native: Install is not supported on %s; use AuditOnly", runtime.GOOS)
+	}
+
+	state, err := n.loadState()
+	if err != nil {
+		close(statusChan)
+		return err
+	}
+
+	for _, name := range apps {
+		am, ok := n.manifest[name]
+		if !ok {
+			statusChan <- niniteclassic.Status{App: name, Status: "Failed", Reason: "not present in manifest"}
+			continue
+		}
+
+		if err := n.installOne(am, state); err != nil {
+			statusChan <- niniteclassic.Status{App: name, Status: "Failed", Reason: err.Error()}
+			continue
+		}
+
+		statusChan <- niniteclassic.Status{App: name, Status: "Installed"}
+	}
+	close(statusChan)
+
+	return n.saveState(state)
+}
+
+func (n Native) installOne(am AppManifest, state map[string]installedApp) error {
+	dest := filepath.Join(n.cachePath, am.Name+filepath.Ext(am.URL))
+	if err := download(am.URL, dest); err != nil {
+		return err
+	}
+	defer os.Remove(dest)
+
+	if am.SHA256 != "" {
+		if err := verifyChecksum(dest, am.SHA256); err != nil {
+			return err
+		}
+	}
+
+	it := am.InstallerType
+	if it == Unknown {
+		detected, err := detectInstallerType(dest)
+		if err != nil {
+			return err
+		}
+		it = detected
+	}
+
+	program, args := silentArgsFor(it, dest)
+	if len(am.SilentArgs) > 0 {
+		args = expandSilentArgs(am.SilentArgs, dest)
+		if it != MSI {
+			program = dest
+		}
+	}
+
+	cmd := exec.Command(program, args...)
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("native: %s exited with code %d", am.Name, exitErr.ExitCode())
+		}
+		return err
+	}
+
+	state[am.Name] = installedApp{Version: am.Version}
+	return nil
+}
+
+// Audit reports, for every app in the manifest, whether it has been
+// installed by this package. Unlike Install, Audit only reads local state
+// and never invokes an installer, so it works on any OS (including
+// Linux/macOS, where the vendor installers this package targets can't run).
+func (n Native) Audit(auditChan chan<- niniteclassic.AppAudit) error {
+	state, err := n.loadState()
+	if err != nil {
+		return err
+	}
+
+	for name := range n.manifest {
+		installed, ok := state[name]
+		status := "Not Installed"
+		if ok {
+			status = "Installed"
+		}
+		auditChan <- niniteclassic.AppAudit{
+			App:       name,
+			Version:   installed.Version,
+			Status:    status,
+			Installed: ok,
+		}
+	}
+	close(auditChan)
+
+	return nil
+}
+
+// SupportsInstall reports whether Install can run vendor installers on the
+// current OS. Only Windows is supported; other platforms are audit-only.
+func SupportsInstall() bool {
+	return runtime.GOOS == "windows"
+}
@@ -0,0 +1,152 @@
+package native
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	niniteclassic "github.com/emmaly/ninite/classic"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "installer.bin")
+	if err := os.WriteFile(path, []byte("hello world"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	// sha256("hello world")
+	const sum = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	if err := verifyChecksum(path, sum); err != nil {
+		t.Errorf("verifyChecksum with matching sum: %v", err)
+	}
+
+	// EqualFold means a differently-cased checksum still matches.
+	if err := verifyChecksum(path, strings.ToUpper(sum)); err != nil {
+		t.Errorf("verifyChecksum with differently-cased sum: %v", err)
+	}
+
+	err := verifyChecksum(path, "0000000000000000000000000000000000000000000000000000000000000")
+	if err == nil {
+		t.Fatal("expected a mismatch error")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("got %q, want it to mention checksum mismatch", err.Error())
+	}
+}
+
+func TestDetectInstallerType(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content []byte
+		want    InstallerType
+		wantErr bool
+	}{
+		{"msi extension", ".msi", []byte("irrelevant"), MSI, false},
+		{"ole compound file", ".bin", []byte{0xD0, 0xCF, 0x11, 0xE0, 0, 0, 0, 0}, MSI, false},
+		{"pe header", ".exe", []byte{'M', 'Z', 0, 0, 0, 0, 0, 0}, EXE, false},
+		{"unrecognized", ".bin", []byte{0, 0, 0, 0, 0, 0, 0, 0}, Unknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "installer"+tt.ext)
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatalf("write fixture: %v", err)
+			}
+
+			got, err := detectInstallerType(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("detectInstallerType: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSilentArgsFor(t *testing.T) {
+	tests := []struct {
+		it          InstallerType
+		wantProgram string
+		wantArgs    []string
+	}{
+		{MSI, "msiexec", []string{"/i", "C:\\installer.msi", "/qn"}},
+		{InnoSetup, "C:\\installer.exe", []string{"/VERYSILENT", "/SUPPRESSMSGBOXES", "/NORESTART"}},
+		{NSIS, "C:\\installer.exe", []string{"/S"}},
+		{Unknown, "C:\\installer.exe", []string{"/S"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.it.String(), func(t *testing.T) {
+			path := "C:\\installer.msi"
+			if tt.it != MSI {
+				path = "C:\\installer.exe"
+			}
+			program, args := silentArgsFor(tt.it, path)
+			if program != tt.wantProgram {
+				t.Errorf("program = %q, want %q", program, tt.wantProgram)
+			}
+			if len(args) != len(tt.wantArgs) {
+				t.Fatalf("args = %v, want %v", args, tt.wantArgs)
+			}
+			for i := range args {
+				if args[i] != tt.wantArgs[i] {
+					t.Errorf("args = %v, want %v", args, tt.wantArgs)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestExpandSilentArgsWithCustomMSISilentArgs(t *testing.T) {
+	// Custom SilentArgs for an MSI installer have "{path}" replaced with the
+	// downloaded installer's path and every other switch kept as given. This
+	// checks only the expansion; which program installOne runs is not checked.
+	path := "C:\\cache\\app.msi"
+	args := expandSilentArgs([]string{"/i", "{path}", "/qn", "/norestart"}, path)
+
+	want := []string{"/i", path, "/qn", "/norestart"}
+	if len(args) != len(want) {
+		t.Fatalf("got %v, want %v", args, want)
+	}
+	for i := range args {
+		if args[i] != want[i] {
+			t.Errorf("got %v, want %v", args, want)
+			break
+		}
+	}
+}
+
+func TestSupportsInstall(t *testing.T) {
+	want := runtime.GOOS == "windows"
+	if got := SupportsInstall(); got != want {
+		t.Errorf("SupportsInstall() = %v, want %v", got, want)
+	}
+}
+
+func TestInstallRejectsUnsupportedOS(t *testing.T) {
+	if SupportsInstall() {
+		t.Skip("only meaningful where Install is unsupported")
+	}
+
+	n, err := NewNative(Manifest{"7-Zip": {Name: "7-Zip"}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("NewNative: %v", err)
+	}
+
+	statusChan := make(chan niniteclassic.Status, 1)
+	if err := n.Install([]string{"7-Zip"}, statusChan); err == nil {
+		t.Fatal("expected an error on an unsupported OS")
+	}
+}